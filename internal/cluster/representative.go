@@ -0,0 +1,197 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+)
+
+// RepresentativeResult is what a Representative strategy picks for one
+// cluster. Index is only meaningful when Synthetic is false; otherwise
+// Point is a computed location and NearestIndex names the actual member
+// closest to it, so callers can source Extra fields from a real record.
+type RepresentativeResult struct {
+	Index        int
+	Point        Point
+	Synthetic    bool
+	NearestIndex int
+}
+
+// Representative picks one representative point for a cluster's members.
+// points is the full input slice; memberIndices are the indices (into
+// points) that belong to the cluster; eps is the DBSCAN epsilon (km) and
+// minPoints the DBSCAN minPoints, used by strategies that need a
+// neighborhood radius or a core-point threshold.
+type Representative interface {
+	Select(points PointList, memberIndices []int, eps float64, minPoints int) RepresentativeResult
+}
+
+// FirstInInput keeps the cluster member with the lowest input index,
+// matching the tool's historic behavior.
+type FirstInInput struct{}
+
+func (FirstInInput) Select(points PointList, memberIndices []int, _ float64, _ int) RepresentativeResult {
+	first := memberIndices[0]
+	for _, idx := range memberIndices {
+		if idx < first {
+			first = idx
+		}
+	}
+	return RepresentativeResult{Index: first, Point: points[first]}
+}
+
+// Centroid returns the mean longitude/latitude of the cluster, with
+// longitudes unwrapped relative to the first member before averaging so a
+// cluster straddling the antimeridian doesn't average towards 0 degrees.
+// The result is synthetic: NearestIndex names the actual member nearest to
+// the computed centroid, for callers to copy Extra from.
+type Centroid struct{}
+
+func (Centroid) Select(points PointList, memberIndices []int, _ float64, _ int) RepresentativeResult {
+	refLon := points[memberIndices[0]][0]
+
+	var sumLon, sumLat float64
+	for _, idx := range memberIndices {
+		sumLon += unwrapLongitude(points[idx][0], refLon)
+		sumLat += points[idx][1]
+	}
+
+	n := float64(len(memberIndices))
+	centroid := Point{
+		normalizeLongitude(sumLon / n),
+		sumLat / n,
+	}
+
+	nearest := nearestMember(points, memberIndices, centroid)
+	return RepresentativeResult{Point: centroid, Synthetic: true, NearestIndex: nearest}
+}
+
+// Medoid returns the cluster member with the minimum sum of haversine
+// distances to every other member. O(k^2) per cluster, acceptable for
+// typical DBSCAN cluster sizes.
+type Medoid struct{}
+
+func (Medoid) Select(points PointList, memberIndices []int, _ float64, _ int) RepresentativeResult {
+	best := memberIndices[0]
+	bestSum := math.Inf(1)
+
+	for _, i := range memberIndices {
+		sum := 0.0
+		for _, j := range memberIndices {
+			if i != j {
+				sum += haversineKM(points[i], points[j])
+			}
+		}
+		if sum < bestSum {
+			bestSum = sum
+			best = i
+		}
+	}
+
+	return RepresentativeResult{Index: best, Point: points[best]}
+}
+
+// DensityPeak returns the cluster's densest core point: the member whose
+// eps-neighborhood (counted over the whole cluster) is both at least
+// minPoints - the DBSCAN definition of "core" - and the largest among the
+// cluster's core points. Border members, which by definition fall short of
+// minPoints neighbors of their own, are never selected.
+type DensityPeak struct{}
+
+func (DensityPeak) Select(points PointList, memberIndices []int, eps float64, minPoints int) RepresentativeResult {
+	best := memberIndices[0]
+	bestCount := -1
+	foundCore := false
+
+	for _, i := range memberIndices {
+		count := 0
+		for _, j := range memberIndices {
+			if haversineKM(points[i], points[j]) <= eps {
+				count++
+			}
+		}
+		if count < minPoints {
+			continue // border point, not a core point
+		}
+		foundCore = true
+		if count > bestCount {
+			bestCount = count
+			best = i
+		}
+	}
+
+	// Every DBSCAN cluster is seeded by at least one core point, but guard
+	// against a degenerate/synthetic cluster (e.g. in tests) smaller than
+	// minPoints by falling back to the densest member overall.
+	if !foundCore {
+		for _, i := range memberIndices {
+			count := 0
+			for _, j := range memberIndices {
+				if haversineKM(points[i], points[j]) <= eps {
+					count++
+				}
+			}
+			if count > bestCount {
+				bestCount = count
+				best = i
+			}
+		}
+	}
+
+	return RepresentativeResult{Index: best, Point: points[best]}
+}
+
+// ParseRepresentative resolves the --representative flag value to a
+// Representative strategy.
+func ParseRepresentative(name string) (Representative, error) {
+	switch name {
+	case "", "first":
+		return FirstInInput{}, nil
+	case "centroid":
+		return Centroid{}, nil
+	case "medoid":
+		return Medoid{}, nil
+	case "density-peak":
+		return DensityPeak{}, nil
+	default:
+		return nil, fmt.Errorf("unknown representative strategy %q", name)
+	}
+}
+
+// unwrapLongitude shifts lon by +/-360 degrees, if needed, so it lies
+// within 180 degrees of ref - this keeps a centroid computation for a
+// cluster straddling the antimeridian from averaging towards 0 degrees.
+func unwrapLongitude(lon, ref float64) float64 {
+	for lon-ref > 180 {
+		lon -= 360
+	}
+	for lon-ref < -180 {
+		lon += 360
+	}
+	return lon
+}
+
+// normalizeLongitude wraps lon back into [-180, 180].
+func normalizeLongitude(lon float64) float64 {
+	for lon > 180 {
+		lon -= 360
+	}
+	for lon < -180 {
+		lon += 360
+	}
+	return lon
+}
+
+// nearestMember returns the member index whose point is closest to target.
+func nearestMember(points PointList, memberIndices []int, target Point) int {
+	nearest := memberIndices[0]
+	nearestDist := haversineKM(points[nearest], target)
+
+	for _, idx := range memberIndices[1:] {
+		if dist := haversineKM(points[idx], target); dist < nearestDist {
+			nearest = idx
+			nearestDist = dist
+		}
+	}
+
+	return nearest
+}
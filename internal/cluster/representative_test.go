@@ -0,0 +1,145 @@
+package cluster
+
+import (
+	"math"
+	"testing"
+)
+
+func squareCluster() (PointList, []int) {
+	points := PointList{
+		{0.0, 0.0},
+		{0.001, 0.0},
+		{0.0, 0.001},
+		{0.001, 0.001},
+	}
+	return points, []int{0, 1, 2, 3}
+}
+
+func TestParseRepresentative(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Representative
+		wantErr bool
+	}{
+		{name: "", want: FirstInInput{}},
+		{name: "first", want: FirstInInput{}},
+		{name: "centroid", want: Centroid{}},
+		{name: "medoid", want: Medoid{}},
+		{name: "density-peak", want: DensityPeak{}},
+		{name: "bogus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRepresentative(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRepresentative(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseRepresentative(%q) = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFirstInInputPicksLowestIndex(t *testing.T) {
+	points, _ := squareCluster()
+	result := FirstInInput{}.Select(points, []int{3, 0, 2, 1}, 0.2, 2)
+	if result.Index != 0 || result.Synthetic {
+		t.Errorf("expected index 0, got %+v", result)
+	}
+}
+
+func TestCentroidIsSynthetic(t *testing.T) {
+	points, members := squareCluster()
+	result := Centroid{}.Select(points, members, 0.2, 2)
+
+	if !result.Synthetic {
+		t.Fatal("expected Centroid to produce a synthetic point")
+	}
+	if result.Point[0] < 0 || result.Point[0] > 0.001 || result.Point[1] < 0 || result.Point[1] > 0.001 {
+		t.Errorf("expected centroid within the square, got %+v", result.Point)
+	}
+}
+
+func TestCentroidNearPoleDoesNotProduceNaN(t *testing.T) {
+	points := PointList{
+		{0.0, 89.999},
+		{90.0, 89.999},
+		{-90.0, 89.999},
+		{180.0, 89.999},
+	}
+	members := []int{0, 1, 2, 3}
+
+	result := Centroid{}.Select(points, members, 0.2, 2)
+
+	if math.IsNaN(result.Point[0]) || math.IsNaN(result.Point[1]) {
+		t.Fatalf("expected a finite centroid near the pole, got %+v", result.Point)
+	}
+}
+
+func TestMedoidPicksClusterMember(t *testing.T) {
+	points, members := squareCluster()
+	result := Medoid{}.Select(points, members, 0.2, 2)
+
+	if result.Synthetic {
+		t.Error("expected Medoid to return an actual member, not a synthetic point")
+	}
+	found := false
+	for _, idx := range members {
+		if idx == result.Index {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected medoid index to be a cluster member, got %d", result.Index)
+	}
+}
+
+func TestDensityPeakPicksClusterMember(t *testing.T) {
+	points, members := squareCluster()
+	result := DensityPeak{}.Select(points, members, 0.2, 2)
+
+	if result.Synthetic {
+		t.Error("expected DensityPeak to return an actual member, not a synthetic point")
+	}
+	found := false
+	for _, idx := range members {
+		if idx == result.Index {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected density peak index to be a cluster member, got %d", result.Index)
+	}
+}
+
+func TestDensityPeakIgnoresBorderPoints(t *testing.T) {
+	// A hub at the origin with three close neighbors (all core points),
+	// plus a lone border point within eps of only the hub. With
+	// minPoints=4, the border point's own neighborhood (itself + the hub)
+	// falls short of minPoints, so it must never be selected even though
+	// it's a cluster member.
+	points := PointList{
+		{0.0, 0.0},          // hub
+		{0.0, 0.00003},      // n1
+		{0.00002, 0.00003},  // n2
+		{-0.00002, 0.00003}, // n3
+		{0.0, -0.0004},      // border, ~45m south of the hub
+	}
+	members := []int{0, 1, 2, 3, 4}
+
+	result := DensityPeak{}.Select(points, members, 0.045, 4)
+
+	if result.Index != 0 {
+		t.Errorf("expected the hub (index 0, the densest core point) to be selected, got %d", result.Index)
+	}
+}
+
+func TestUnwrapAndNormalizeLongitude(t *testing.T) {
+	if got := unwrapLongitude(179.0, -179.0); got != -181.0 {
+		t.Errorf("unwrapLongitude(179, -179) = %v, want -181", got)
+	}
+	if got := normalizeLongitude(190.0); got != -170.0 {
+		t.Errorf("normalizeLongitude(190) = %v, want -170", got)
+	}
+}
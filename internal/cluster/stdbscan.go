@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	stUnclassified = 0
+	stNoise        = -1
+)
+
+// STDBScan runs a spatiotemporal variant of DBSCAN: a point q is in the
+// epsilon-neighborhood of p only if both the haversine distance between
+// them is within epsSpatial (km) AND their timestamps are within
+// epsTemporal of each other. Core-point expansion and label assignment
+// otherwise follow classical DBSCAN. times must be parallel to points.
+//
+// Returns the discovered clusters plus the indices of noise points, same
+// shape as DBScan.
+func STDBScan(points PointList, times []time.Time, epsSpatial float64, epsTemporal time.Duration, minPoints int) ([]Cluster, []int) {
+	n := len(points)
+	labels := make([]int, n) // 0 = unclassified, -1 = noise, else clusterID+1
+
+	nextClusterID := 0
+	for i := 0; i < n; i++ {
+		if labels[i] != stUnclassified {
+			continue
+		}
+
+		neighbors := stRegionQuery(points, times, i, epsSpatial, epsTemporal)
+		if len(neighbors) < minPoints {
+			labels[i] = stNoise
+			continue
+		}
+
+		labels[i] = nextClusterID + 1
+		stExpandCluster(points, times, labels, neighbors, nextClusterID, epsSpatial, epsTemporal, minPoints)
+		nextClusterID++
+	}
+
+	clusters := make([]Cluster, nextClusterID)
+	for c := range clusters {
+		clusters[c] = Cluster{C: c}
+	}
+	var noise []int
+	for idx, label := range labels {
+		if label == stNoise {
+			noise = append(noise, idx)
+			continue
+		}
+		clusters[label-1].Points = append(clusters[label-1].Points, idx)
+	}
+
+	return clusters, noise
+}
+
+// stExpandCluster grows clusterID outward from an initial neighbor set,
+// following the same border/core-point rules as classical DBSCAN.
+func stExpandCluster(points PointList, times []time.Time, labels []int, seedNeighbors []int, clusterID int, epsSpatial float64, epsTemporal time.Duration, minPoints int) {
+	queue := append([]int{}, seedNeighbors...)
+
+	for len(queue) > 0 {
+		idx := queue[0]
+		queue = queue[1:]
+
+		if labels[idx] == stNoise {
+			labels[idx] = clusterID + 1
+			continue
+		}
+		if labels[idx] != stUnclassified {
+			continue
+		}
+
+		labels[idx] = clusterID + 1
+
+		neighbors := stRegionQuery(points, times, idx, epsSpatial, epsTemporal)
+		if len(neighbors) >= minPoints {
+			queue = append(queue, neighbors...)
+		}
+	}
+}
+
+// stRegionQuery returns the indices within epsSpatial/epsTemporal of
+// points[idx], including idx itself.
+func stRegionQuery(points PointList, times []time.Time, idx int, epsSpatial float64, epsTemporal time.Duration) []int {
+	var neighbors []int
+	for j := range points {
+		if haversineKM(points[idx], points[j]) > epsSpatial {
+			continue
+		}
+		if absDuration(times[idx].Sub(times[j])) > epsTemporal {
+			continue
+		}
+		neighbors = append(neighbors, j)
+	}
+	return neighbors
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// haversineKM returns the great-circle distance between two points, in
+// kilometers. Point is [2]float64 where [0]=Lon, [1]=Lat.
+func haversineKM(a, b Point) float64 {
+	const earthRadiusKM = 6371.0
+
+	lat1, lon1 := a[1]*math.Pi/180, a[0]*math.Pi/180
+	lat2, lon2 := b[1]*math.Pi/180, b[0]*math.Pi/180
+
+	dLat := lat2 - lat1
+	dLon := lon2 - lon1
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKM * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSTDBScanSplitsBySpaceAndTime(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	points := PointList{
+		{-74.0060, 40.7128}, // NYC, t=0
+		{-74.0061, 40.7129}, // NYC, t=1s
+		{-74.0062, 40.7130}, // NYC, t=10h (outside epsTemporal)
+	}
+	times := []time.Time{
+		base,
+		base.Add(1 * time.Second),
+		base.Add(10 * time.Hour),
+	}
+
+	clusters, noise := STDBScan(points, times, 0.1, 1*time.Minute, 2)
+
+	if len(clusters) != 1 {
+		t.Fatalf("expected 1 cluster, got %d", len(clusters))
+	}
+	if len(clusters[0].Points) != 2 {
+		t.Errorf("expected 2 points in the cluster, got %d", len(clusters[0].Points))
+	}
+	if len(noise) != 1 || noise[0] != 2 {
+		t.Errorf("expected point 2 to be noise (outside epsTemporal), got %v", noise)
+	}
+}
+
+func TestHaversineKMKnownDistance(t *testing.T) {
+	nyc := Point{-74.0060, 40.7128}
+	la := Point{-118.2437, 34.0522}
+
+	got := haversineKM(nyc, la)
+	want := 3936.0 // approximate NYC-LA great-circle distance in km
+
+	if diff := got - want; diff > 50 || diff < -50 {
+		t.Errorf("haversineKM(NYC, LA) = %.1f, want ~%.1f", got, want)
+	}
+}
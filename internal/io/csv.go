@@ -0,0 +1,173 @@
+package io
+
+import (
+	"encoding/csv"
+	stdio "io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CSVColumns names the columns to read/write lat/lon and the optional
+// timestamp/id columns. Zero-value Timestamp/ID mean "not present". ID is
+// not treated specially by CSVSource or CSVSink: an id column is carried in
+// Extra like any other column.
+type CSVColumns struct {
+	Lat       string
+	Lon       string
+	Timestamp string
+	ID        string
+}
+
+// DefaultCSVColumns matches the tool's historic two-column contract.
+var DefaultCSVColumns = CSVColumns{Lat: "latitude", Lon: "longitude"}
+
+// CSVSource reads PointRecord values from a struct-tagged CSV: the header
+// row is matched against Columns to locate lat/lon (and optional
+// timestamp), and every other column is preserved per-record in Extra.
+type CSVSource struct {
+	r       stdio.Reader
+	Columns CSVColumns
+}
+
+// NewCSVSource builds a CSVSource over r using the given column mapping.
+func NewCSVSource(r stdio.Reader, columns CSVColumns) *CSVSource {
+	return &CSVSource{r: r, Columns: columns}
+}
+
+func (s *CSVSource) Read() ([]PointRecord, error) {
+	reader := csv.NewReader(s.r)
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+
+	latIdx, ok := index[s.Columns.Lat]
+	if !ok {
+		return nil, &ColumnNotFoundError{Column: s.Columns.Lat}
+	}
+	lonIdx, ok := index[s.Columns.Lon]
+	if !ok {
+		return nil, &ColumnNotFoundError{Column: s.Columns.Lon}
+	}
+	tsIdx, hasTS := -1, false
+	if s.Columns.Timestamp != "" {
+		tsIdx, hasTS = index[s.Columns.Timestamp]
+	}
+
+	records := make([]PointRecord, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		lat, err1 := strconv.ParseFloat(row[latIdx], 64)
+		lon, err2 := strconv.ParseFloat(row[lonIdx], 64)
+		if err1 != nil || err2 != nil {
+			continue // Skip invalid rows, matching the historic CSV reader
+		}
+
+		record := PointRecord{Lat: lat, Lon: lon, Extra: map[string]string{}}
+		if hasTS && tsIdx >= 0 && tsIdx < len(row) {
+			if ts, err := time.Parse(time.RFC3339, row[tsIdx]); err == nil {
+				record.Timestamp = &ts
+			}
+		}
+
+		for name, idx := range index {
+			if idx == latIdx || idx == lonIdx || (hasTS && idx == tsIdx) {
+				continue
+			}
+			if idx < len(row) {
+				record.Extra[name] = row[idx]
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// ColumnNotFoundError is returned when a configured column name is missing
+// from the CSV header.
+type ColumnNotFoundError struct {
+	Column string
+}
+
+func (e *ColumnNotFoundError) Error() string {
+	return "column not found in CSV header: " + e.Column
+}
+
+// CSVSink writes PointRecord values as struct-tagged CSV: lat/lon (and
+// timestamp, when Columns.Timestamp is set) go in their named columns, and
+// every key seen across Extra maps becomes its own column.
+type CSVSink struct {
+	w       stdio.Writer
+	Columns CSVColumns
+}
+
+// NewCSVSink builds a CSVSink over w using the given column mapping.
+func NewCSVSink(w stdio.Writer, columns CSVColumns) *CSVSink {
+	return &CSVSink{w: w, Columns: columns}
+}
+
+func (s *CSVSink) Write(records []PointRecord) error {
+	writer := csv.NewWriter(s.w)
+	defer writer.Flush()
+
+	extraNames := collectExtraNames(records)
+
+	header := []string{s.Columns.Lat, s.Columns.Lon}
+	if s.Columns.Timestamp != "" {
+		header = append(header, s.Columns.Timestamp)
+	}
+	header = append(header, extraNames...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		row := []string{
+			strconv.FormatFloat(record.Lat, 'f', -1, 64),
+			strconv.FormatFloat(record.Lon, 'f', -1, 64),
+		}
+		if s.Columns.Timestamp != "" {
+			ts := ""
+			if record.Timestamp != nil {
+				ts = record.Timestamp.Format(time.RFC3339)
+			}
+			row = append(row, ts)
+		}
+		for _, name := range extraNames {
+			row = append(row, record.Extra[name])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectExtraNames gathers a stable, sorted set of Extra keys across all
+// records so every row in the output has the same column count.
+func collectExtraNames(records []PointRecord) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, record := range records {
+		for name := range record.Extra {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,83 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	stdio "io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/transform"
+)
+
+// utf8BOM is the UTF-8 encoding of U+FEFF, the byte-order mark. Once a
+// stream has been decoded to UTF-8 it's the only BOM form left to strip,
+// regardless of whether the source was UTF-8 or UTF-16.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// resolveEncoding looks up name (e.g. "utf-8", "utf-16le", "gbk",
+// "shift-jis", "iso-8859-1") via the IANA character set registry. An empty
+// name means UTF-8, the tool's historic assumption.
+//
+// ianaindex is strict about underscores vs. hyphens in a handful of names
+// (it knows "shift_jis" but not "shift-jis"), so a lookup that fails is
+// retried with hyphens normalized to underscores before giving up.
+func resolveEncoding(name string) (encoding.Encoding, error) {
+	if name == "" {
+		return encoding.Nop, nil
+	}
+
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err != nil || enc == nil {
+		if normalized := strings.ReplaceAll(name, "-", "_"); normalized != name {
+			enc, err = ianaindex.IANA.Encoding(normalized)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unknown encoding %q: %w", name, err)
+	}
+	if enc == nil {
+		return nil, fmt.Errorf("unknown encoding %q", name)
+	}
+
+	return enc, nil
+}
+
+// DecodingReader wraps r so bytes in the named encoding are transformed to
+// UTF-8 as they're read, and strips a leading UTF-8/UTF-16 byte-order mark
+// so header detection still works on the first record's first field.
+func DecodingReader(r stdio.Reader, encodingName string) (stdio.Reader, error) {
+	enc, err := resolveEncoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := transform.NewReader(r, enc.NewDecoder())
+	return stripBOM(decoded), nil
+}
+
+// EncodingWriter wraps w so UTF-8 bytes written to it are transformed into
+// the named encoding before hitting the underlying writer.
+func EncodingWriter(w stdio.Writer, encodingName string) (stdio.Writer, error) {
+	enc, err := resolveEncoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	return transform.NewWriter(w, enc.NewEncoder()), nil
+}
+
+// stripBOM peeks the first three bytes of r and discards them if they are
+// a UTF-8 byte-order mark.
+func stripBOM(r stdio.Reader) stdio.Reader {
+	br := bufio.NewReader(r)
+
+	peeked, err := br.Peek(len(utf8BOM))
+	if err == nil && bytes.Equal(peeked, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	return br
+}
@@ -0,0 +1,104 @@
+package io
+
+import (
+	"bytes"
+	stdio "io"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+func TestDecodingReaderPassesThroughUTF8(t *testing.T) {
+	reader, err := DecodingReader(bytes.NewReader([]byte("latitude,longitude\n40.7128,-74.0060\n")), "")
+	if err != nil {
+		t.Fatalf("DecodingReader() returned error: %v", err)
+	}
+
+	got, err := stdio.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() returned error: %v", err)
+	}
+	if string(got) != "latitude,longitude\n40.7128,-74.0060\n" {
+		t.Errorf("unexpected decoded content: %q", got)
+	}
+}
+
+func TestDecodingReaderStripsUTF8BOM(t *testing.T) {
+	input := append([]byte{0xEF, 0xBB, 0xBF}, []byte("latitude,longitude\n")...)
+
+	reader, err := DecodingReader(bytes.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("DecodingReader() returned error: %v", err)
+	}
+
+	got, err := stdio.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() returned error: %v", err)
+	}
+	if string(got) != "latitude,longitude\n" {
+		t.Errorf("expected BOM to be stripped, got %q", got)
+	}
+}
+
+func TestResolveEncodingUnknown(t *testing.T) {
+	if _, err := resolveEncoding("not-a-real-encoding"); err == nil {
+		t.Error("expected an error for an unknown encoding name")
+	}
+}
+
+func TestDecodingReaderTranscodesNamedEncodings(t *testing.T) {
+	const want = "latitude,longitude\n40.7128,-74.0060\n"
+
+	tests := []struct {
+		name     string
+		enc      func() stdio.Reader
+		encoding string
+	}{
+		{name: "utf-16le", enc: func() stdio.Reader {
+			return encodeWith(t, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), want)
+		}, encoding: "utf-16le"},
+		{name: "gbk", enc: func() stdio.Reader { return encodeWith(t, simplifiedchinese.GBK, want) }, encoding: "gbk"},
+		{name: "shift-jis (hyphenated)", enc: func() stdio.Reader { return encodeWith(t, japanese.ShiftJIS, want) }, encoding: "shift-jis"},
+		{name: "shift_jis (underscored)", enc: func() stdio.Reader { return encodeWith(t, japanese.ShiftJIS, want) }, encoding: "shift_jis"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := DecodingReader(tt.enc(), tt.encoding)
+			if err != nil {
+				t.Fatalf("DecodingReader(%q) returned error: %v", tt.encoding, err)
+			}
+
+			got, err := stdio.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() returned error: %v", err)
+			}
+			if string(got) != want {
+				t.Errorf("decoded %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// encodeWith transcodes s from UTF-8 into enc's encoding using the same
+// golang.org/x/text machinery DecodingReader/EncodingWriter are built on.
+func encodeWith(t *testing.T, enc interface {
+	NewEncoder() *encoding.Encoder
+}, s string) stdio.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := transform.NewWriter(&buf, enc.NewEncoder())
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatalf("encoding fixture text: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("closing encoder: %v", err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
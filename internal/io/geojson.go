@@ -0,0 +1,123 @@
+package io
+
+import (
+	"encoding/json"
+	stdio "io"
+)
+
+// geoJSONFeatureCollection and geoJSONFeature mirror just enough of the
+// GeoJSON spec (RFC 7946) for Point features: coordinates map to a point
+// and properties map to Extra.
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// GeoJSONSource reads PointRecord values out of a FeatureCollection of
+// Point features.
+type GeoJSONSource struct {
+	r stdio.Reader
+}
+
+// NewGeoJSONSource builds a GeoJSONSource over r.
+func NewGeoJSONSource(r stdio.Reader) *GeoJSONSource {
+	return &GeoJSONSource{r: r}
+}
+
+func (s *GeoJSONSource) Read() ([]PointRecord, error) {
+	var collection geoJSONFeatureCollection
+	if err := json.NewDecoder(s.r).Decode(&collection); err != nil {
+		return nil, err
+	}
+
+	records := make([]PointRecord, 0, len(collection.Features))
+	for _, feature := range collection.Features {
+		if feature.Geometry.Type != "Point" || len(feature.Geometry.Coordinates) < 2 {
+			continue // Only Point geometries carry a single lat/lon pair
+		}
+
+		record := PointRecord{
+			Lon:   feature.Geometry.Coordinates[0],
+			Lat:   feature.Geometry.Coordinates[1],
+			Extra: map[string]string{},
+		}
+		for key, value := range feature.Properties {
+			record.Extra[key] = propertyToString(value)
+		}
+		if raw, ok := record.Extra["timestamp"]; ok {
+			if ts, err := parseTimestamp(raw); err == nil {
+				record.Timestamp = &ts
+				delete(record.Extra, "timestamp")
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func propertyToString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+}
+
+// GeoJSONSink writes PointRecord values as a Point FeatureCollection,
+// copying Extra back into properties.
+type GeoJSONSink struct {
+	w stdio.Writer
+}
+
+// NewGeoJSONSink builds a GeoJSONSink over w.
+func NewGeoJSONSink(w stdio.Writer) *GeoJSONSink {
+	return &GeoJSONSink{w: w}
+}
+
+func (s *GeoJSONSink) Write(records []PointRecord) error {
+	collection := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, 0, len(records)),
+	}
+
+	for _, record := range records {
+		properties := map[string]interface{}{}
+		for key, value := range record.Extra {
+			properties[key] = value
+		}
+		if record.Timestamp != nil {
+			properties["timestamp"] = record.Timestamp.Format("2006-01-02T15:04:05Z07:00")
+		}
+
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type: "Feature",
+			Geometry: geoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{record.Lon, record.Lat},
+			},
+			Properties: properties,
+		})
+	}
+
+	encoder := json.NewEncoder(s.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(collection)
+}
@@ -0,0 +1,75 @@
+// Package io provides pluggable input and output for geo point records.
+//
+// A Source produces PointRecord values from some underlying format (CSV,
+// GeoJSON, NDJSON, ...) and a Sink consumes them, writing filtered results
+// back out in the same or a different format. Keeping the two sides of the
+// pipeline behind interfaces lets the CLI mix and match formats without the
+// rest of the tool knowing which one is in play.
+package io
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PointRecord is a single geo point flowing through the pipeline. Lat/Lon
+// are always populated; Timestamp and Extra are format-dependent and may be
+// nil/empty when the underlying format doesn't carry that information.
+type PointRecord struct {
+	Lat       float64
+	Lon       float64
+	Timestamp *time.Time
+	Extra     map[string]string
+}
+
+// Source reads point records from an underlying format.
+type Source interface {
+	Read() ([]PointRecord, error)
+}
+
+// Sink writes (filtered) point records to an underlying format.
+type Sink interface {
+	Write(records []PointRecord) error
+}
+
+// Format identifies one of the supported record formats.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatGeoJSON Format = "geojson"
+	FormatNDJSON  Format = "ndjson"
+)
+
+// parseTimestamp parses the RFC3339 timestamps used by the GeoJSON and
+// NDJSON formats.
+func parseTimestamp(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339, value)
+}
+
+// DetectFormat resolves a Format from an explicit --input-format/
+// --output-format flag value, falling back to the file extension when the
+// flag is empty (auto-detect).
+func DetectFormat(explicit, filename string) (Format, error) {
+	if explicit != "" {
+		switch Format(explicit) {
+		case FormatCSV, FormatGeoJSON, FormatNDJSON:
+			return Format(explicit), nil
+		default:
+			return "", fmt.Errorf("unknown format %q", explicit)
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".geojson", ".json":
+		return FormatGeoJSON, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	case ".csv", "":
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("cannot auto-detect format for %q, pass --input-format/--output-format", filename)
+	}
+}
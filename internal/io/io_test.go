@@ -0,0 +1,166 @@
+package io
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVSourceReadsExtraColumns(t *testing.T) {
+	input := "latitude,longitude,name\n40.7128,-74.0060,Times Square\n40.7500,-73.9900,Central Park\n"
+
+	source := NewCSVSource(strings.NewReader(input), DefaultCSVColumns)
+	records, err := source.Read()
+	if err != nil {
+		t.Fatalf("Read() returned error: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Lat != 40.7128 || records[0].Lon != -74.0060 {
+		t.Errorf("unexpected lat/lon: %+v", records[0])
+	}
+	if records[0].Extra["name"] != "Times Square" {
+		t.Errorf("expected name Extra to be preserved, got %q", records[0].Extra["name"])
+	}
+}
+
+func TestCSVSourceMissingColumn(t *testing.T) {
+	input := "lat,lon\n40.7128,-74.0060\n"
+
+	source := NewCSVSource(strings.NewReader(input), DefaultCSVColumns)
+	if _, err := source.Read(); err == nil {
+		t.Error("expected an error for a missing configured column")
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	records := []PointRecord{
+		{Lat: 1.5, Lon: 2.5, Extra: map[string]string{"id": "a"}},
+		{Lat: 3.5, Lon: 4.5, Extra: map[string]string{"id": "b"}},
+	}
+
+	var buf bytes.Buffer
+	sink := NewCSVSink(&buf, DefaultCSVColumns)
+	if err := sink.Write(records); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	source := NewCSVSource(&buf, DefaultCSVColumns)
+	got, err := source.Read()
+	if err != nil {
+		t.Fatalf("Read() returned error: %v", err)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("expected %d records back, got %d", len(records), len(got))
+	}
+	if got[0].Extra["id"] != "a" || got[1].Extra["id"] != "b" {
+		t.Errorf("Extra columns did not round-trip: %+v", got)
+	}
+}
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	records := []PointRecord{
+		{Lat: 1.5, Lon: 2.5, Extra: map[string]string{"id": "a"}},
+		{Lat: 3.5, Lon: 4.5, Extra: map[string]string{"id": "b"}},
+	}
+
+	var buf bytes.Buffer
+	sink := NewNDJSONSink(&buf)
+	if err := sink.Write(records); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	source := NewNDJSONSource(&buf)
+	got, err := source.Read()
+	if err != nil {
+		t.Fatalf("Read() returned error: %v", err)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("expected %d records back, got %d", len(records), len(got))
+	}
+}
+
+func TestGeoJSONRoundTrip(t *testing.T) {
+	records := []PointRecord{
+		{Lat: 1.5, Lon: 2.5, Extra: map[string]string{"id": "a"}},
+		{Lat: 3.5, Lon: 4.5, Extra: map[string]string{"id": "b"}},
+	}
+
+	var buf bytes.Buffer
+	sink := NewGeoJSONSink(&buf)
+	if err := sink.Write(records); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	source := NewGeoJSONSource(&buf)
+	got, err := source.Read()
+	if err != nil {
+		t.Fatalf("Read() returned error: %v", err)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("expected %d records back, got %d", len(records), len(got))
+	}
+	if got[0].Lat != 1.5 || got[0].Lon != 2.5 {
+		t.Errorf("unexpected lat/lon: %+v", got[0])
+	}
+	if got[0].Extra["id"] != "a" || got[1].Extra["id"] != "b" {
+		t.Errorf("Extra columns did not round-trip: %+v", got)
+	}
+}
+
+func TestGeoJSONRoundTripPreservesTimestamp(t *testing.T) {
+	ts := time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC)
+	records := []PointRecord{
+		{Lat: 1.5, Lon: 2.5, Extra: map[string]string{"id": "a"}, Timestamp: &ts},
+	}
+
+	var buf bytes.Buffer
+	sink := NewGeoJSONSink(&buf)
+	if err := sink.Write(records); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	source := NewGeoJSONSource(&buf)
+	got, err := source.Read()
+	if err != nil {
+		t.Fatalf("Read() returned error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 record back, got %d", len(got))
+	}
+	if got[0].Timestamp == nil || !got[0].Timestamp.Equal(ts) {
+		t.Errorf("expected timestamp %v to round-trip, got %+v", ts, got[0].Timestamp)
+	}
+	if _, ok := got[0].Extra["timestamp"]; ok {
+		t.Errorf("expected timestamp to be lifted out of Extra, got %+v", got[0].Extra)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		explicit string
+		filename string
+		want     Format
+		wantErr  bool
+	}{
+		{filename: "points.csv", want: FormatCSV},
+		{filename: "points.geojson", want: FormatGeoJSON},
+		{filename: "points.ndjson", want: FormatNDJSON},
+		{explicit: "geojson", filename: "points.csv", want: FormatGeoJSON},
+		{filename: "points.unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := DetectFormat(tt.explicit, tt.filename)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("DetectFormat(%q, %q) error = %v, wantErr %v", tt.explicit, tt.filename, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.explicit, tt.filename, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package io
+
+import (
+	"bufio"
+	"encoding/json"
+	stdio "io"
+)
+
+// ndjsonRecord is the on-the-wire shape of one NDJSON line.
+type ndjsonRecord struct {
+	Lat       float64           `json:"lat"`
+	Lon       float64           `json:"lon"`
+	Timestamp string            `json:"timestamp,omitempty"`
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+// NDJSONSource reads PointRecord values from newline-delimited JSON,
+// one record per line, suitable for streaming pipelines.
+type NDJSONSource struct {
+	r stdio.Reader
+}
+
+// NewNDJSONSource builds an NDJSONSource over r.
+func NewNDJSONSource(r stdio.Reader) *NDJSONSource {
+	return &NDJSONSource{r: r}
+}
+
+func (s *NDJSONSource) Read() ([]PointRecord, error) {
+	var records []PointRecord
+
+	scanner := bufio.NewScanner(s.r)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var raw ndjsonRecord
+		if err := json.Unmarshal(line, &raw); err != nil {
+			return nil, err
+		}
+
+		record := PointRecord{Lat: raw.Lat, Lon: raw.Lon, Extra: raw.Extra}
+		if raw.Timestamp != "" {
+			if ts, err := parseTimestamp(raw.Timestamp); err == nil {
+				record.Timestamp = &ts
+			}
+		}
+
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// NDJSONSink writes PointRecord values as newline-delimited JSON.
+type NDJSONSink struct {
+	w stdio.Writer
+}
+
+// NewNDJSONSink builds an NDJSONSink over w.
+func NewNDJSONSink(w stdio.Writer) *NDJSONSink {
+	return &NDJSONSink{w: w}
+}
+
+func (s *NDJSONSink) Write(records []PointRecord) error {
+	encoder := json.NewEncoder(s.w)
+	for _, record := range records {
+		raw := ndjsonRecord{Lat: record.Lat, Lon: record.Lon, Extra: record.Extra}
+		if record.Timestamp != nil {
+			raw.Timestamp = record.Timestamp.Format("2006-01-02T15:04:05Z07:00")
+		}
+		if err := encoder.Encode(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
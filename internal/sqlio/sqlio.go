@@ -0,0 +1,167 @@
+// Package sqlio reads and writes geo points directly against a SQL
+// database (Postgres, MySQL, SQLite, ...) via database/sql and squirrel,
+// so the DBSCAN pipeline can run against a table without a CSV
+// intermediary.
+package sqlio
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	pointio "go-dbscan-filter/internal/io"
+)
+
+// SQLSource reads every PointRecord from table in a single query. DBSCAN
+// needs the full point set to cluster at all, so - like the CSV/GeoJSON/
+// NDJSON sources - this loads the whole table into memory; it does not
+// page or stream rows. Every column other than LatCol/LonCol is preserved
+// per-record in Extra.
+type SQLSource struct {
+	db     *sql.DB
+	table  string
+	latCol string
+	lonCol string
+	where  sq.Sqlizer
+
+	placeholderFormat sq.PlaceholderFormat
+}
+
+// NewSQLSource builds a SQLSource over table, matching latCol/lonCol to
+// the columns carrying latitude/longitude. where may be nil to select all
+// rows. driverName is the database/sql driver name (e.g. "postgres",
+// "mysql", "sqlite3") and selects the bind-parameter syntax squirrel emits.
+func NewSQLSource(db *sql.DB, table string, latCol, lonCol string, where sq.Sqlizer, driverName string) *SQLSource {
+	return &SQLSource{db: db, table: table, latCol: latCol, lonCol: lonCol, where: where, placeholderFormat: placeholderFormatFor(driverName)}
+}
+
+func (s *SQLSource) Read() ([]pointio.PointRecord, error) {
+	builder := sq.Select("*").From(s.table).PlaceholderFormat(s.placeholderFormat)
+	if s.where != nil {
+		builder = builder.Where(s.where)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.scanRows(query, args)
+}
+
+func (s *SQLSource) scanRows(query string, args []interface{}) ([]pointio.PointRecord, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []pointio.PointRecord
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		scanTargets := make([]interface{}, len(columns))
+		for i := range values {
+			scanTargets[i] = &values[i]
+		}
+		if err := rows.Scan(scanTargets...); err != nil {
+			return nil, err
+		}
+
+		record := pointio.PointRecord{Extra: map[string]string{}}
+		for i, name := range columns {
+			switch name {
+			case s.latCol:
+				record.Lat, err = toFloat64(values[i])
+			case s.lonCol:
+				record.Lon, err = toFloat64(values[i])
+			default:
+				record.Extra[name] = fmt.Sprintf("%v", values[i])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", name, err)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case []byte:
+		var f float64
+		_, err := fmt.Sscanf(string(v), "%g", &f)
+		return f, err
+	case string:
+		var f float64
+		_, err := fmt.Sscanf(v, "%g", &f)
+		return f, err
+	default:
+		return 0, fmt.Errorf("unsupported lat/lon column type %T", value)
+	}
+}
+
+// SQLSink writes PointRecord values as rows in table, via a squirrel
+// Insert per record, round-tripping each record's Extra map into its own
+// columns alongside lat/lon.
+type SQLSink struct {
+	db    *sql.DB
+	table string
+
+	placeholderFormat sq.PlaceholderFormat
+}
+
+// NewSQLSink builds a SQLSink that inserts into table. driverName is the
+// database/sql driver name (e.g. "postgres", "mysql", "sqlite3") and
+// selects the bind-parameter syntax squirrel emits.
+func NewSQLSink(db *sql.DB, table string, driverName string) *SQLSink {
+	return &SQLSink{db: db, table: table, placeholderFormat: placeholderFormatFor(driverName)}
+}
+
+func (s *SQLSink) Write(records []pointio.PointRecord) error {
+	for _, record := range records {
+		columns := []string{"lat", "lon"}
+		values := []interface{}{record.Lat, record.Lon}
+		for name, value := range record.Extra {
+			columns = append(columns, name)
+			values = append(values, value)
+		}
+
+		query, args, err := sq.Insert(s.table).Columns(columns...).Values(values...).PlaceholderFormat(s.placeholderFormat).ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// placeholderFormatFor maps a database/sql driver name to the bind-parameter
+// syntax its backend expects. Postgres drivers use positional "$1, $2, ..."
+// placeholders; everything else defaults to squirrel's "?" convention
+// (MySQL, SQLite, and friends).
+func placeholderFormatFor(driverName string) sq.PlaceholderFormat {
+	switch driverName {
+	case "postgres", "pgx", "pq":
+		return sq.Dollar
+	default:
+		return sq.Question
+	}
+}
@@ -0,0 +1,122 @@
+package sqlio
+
+import (
+	"database/sql"
+	"sort"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	pointio "go-dbscan-filter/internal/io"
+)
+
+func TestToFloat64(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    float64
+		wantErr bool
+	}{
+		{name: "float64", value: float64(40.7128), want: 40.7128},
+		{name: "int64", value: int64(40), want: 40},
+		{name: "bytes", value: []byte("40.7128"), want: 40.7128},
+		{name: "string", value: "40.7128", want: 40.7128},
+		{name: "unsupported", value: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toFloat64(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toFloat64(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("toFloat64(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceholderFormatFor(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{driver: "postgres", want: "$1 = $2"},
+		{driver: "pgx", want: "$1 = $2"},
+		{driver: "mysql", want: "? = ?"},
+		{driver: "sqlite3", want: "? = ?"},
+		{driver: "", want: "? = ?"},
+	}
+
+	for _, tt := range tests {
+		got, err := placeholderFormatFor(tt.driver).ReplacePlaceholders("? = ?")
+		if err != nil {
+			t.Fatalf("ReplacePlaceholders() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("placeholderFormatFor(%q) produced %q, want %q", tt.driver, got, tt.want)
+		}
+	}
+}
+
+func TestSQLSourceAndSinkRoundTrip(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("opening in-memory sqlite: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`CREATE TABLE points (lat REAL, lon REAL, name TEXT)`); err != nil {
+		t.Fatalf("creating source table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO points (lat, lon, name) VALUES (?, ?, ?), (?, ?, ?), (?, ?, ?)`,
+		40.7128, -74.0060, "a",
+		40.7130, -74.0062, "b",
+		41.0000, -74.0000, "c",
+	); err != nil {
+		t.Fatalf("seeding source table: %v", err)
+	}
+
+	source := NewSQLSource(db, "points", "lat", "lon", nil, "sqlite3")
+	records, err := source.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	names := make([]string, len(records))
+	for i, r := range records {
+		if r.Extra["name"] == "" {
+			t.Errorf("record %+v missing name in Extra", r)
+		}
+		names[i] = r.Extra["name"]
+	}
+	sort.Strings(names)
+	if names[0] != "a" || names[1] != "b" || names[2] != "c" {
+		t.Errorf("unexpected names read back: %v", names)
+	}
+
+	if _, err := db.Exec(`CREATE TABLE filtered (lat REAL, lon REAL, name TEXT)`); err != nil {
+		t.Fatalf("creating sink table: %v", err)
+	}
+
+	sink := NewSQLSink(db, "filtered", "sqlite3")
+	toWrite := []pointio.PointRecord{
+		{Lat: 40.7128, Lon: -74.0060, Extra: map[string]string{"name": "a"}},
+		{Lat: 41.0000, Lon: -74.0000, Extra: map[string]string{"name": "c"}},
+	}
+	if err := sink.Write(toWrite); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM filtered`).Scan(&count); err != nil {
+		t.Fatalf("counting sink rows: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 rows written to sink table, got %d", count)
+	}
+}
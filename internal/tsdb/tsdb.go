@@ -0,0 +1,220 @@
+// Package tsdb reads geo points out of InfluxDB query results and writes
+// filtered cluster representatives back as line-protocol points, so the
+// DBSCAN pipeline can run directly against a time-series database instead
+// of only static files.
+package tsdb
+
+import (
+	"bufio"
+	"fmt"
+	stdio "io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	pointio "go-dbscan-filter/internal/io"
+)
+
+// Client is a minimal InfluxDB v2 HTTP client: enough to run a Flux query
+// and write line-protocol points, without pulling in the full influxdb
+// client SDK.
+type Client struct {
+	BaseURL    string
+	Token      string
+	Org        string
+	httpClient *http.Client
+}
+
+// NewClient builds a Client against an InfluxDB v2 instance at baseURL.
+func NewClient(baseURL, token, org string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token, Org: org, httpClient: &http.Client{}}
+}
+
+// Query runs a Flux query and returns its rows as InfluxDB's annotated CSV,
+// decoded into column -> value maps.
+func (c *Client) Query(flux string) ([]map[string]string, error) {
+	endpoint := fmt.Sprintf("%s/api/v2/query?org=%s", c.BaseURL, url.QueryEscape(c.Org))
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(flux))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Token "+c.Token)
+	req.Header.Set("Content-Type", "application/vnd.flux")
+	req.Header.Set("Accept", "application/csv")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("influx query returned status %s", resp.Status)
+	}
+
+	return parseAnnotatedCSV(resp.Body)
+}
+
+// WriteLineProtocol writes pre-built line-protocol points to bucket.
+func (c *Client) WriteLineProtocol(bucket string, lines []string) error {
+	endpoint := fmt.Sprintf("%s/api/v2/write?org=%s&bucket=%s&precision=ns", c.BaseURL, url.QueryEscape(c.Org), url.QueryEscape(bucket))
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+c.Token)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("influx write returned status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// parseAnnotatedCSV decodes InfluxDB's annotated CSV query response into
+// one map per data row, keyed by column name.
+func parseAnnotatedCSV(body stdio.Reader) ([]map[string]string, error) {
+	scanner := bufio.NewScanner(body)
+	var header []string
+	var rows []map[string]string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		if header == nil {
+			header = fields
+			continue
+		}
+
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(fields) {
+				row[name] = fields[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, scanner.Err()
+}
+
+// Source reads PointRecord values from an InfluxDB Flux query result.
+// LatField/LonField/TimeField name the result columns carrying latitude,
+// longitude, and the row timestamp.
+type Source struct {
+	client    *Client
+	query     string
+	LatField  string
+	LonField  string
+	TimeField string
+}
+
+// NewSource builds a Source that runs query against client.
+func NewSource(client *Client, query, latField, lonField, timeField string) *Source {
+	return &Source{client: client, query: query, LatField: latField, LonField: lonField, TimeField: timeField}
+}
+
+func (s *Source) Read() ([]pointio.PointRecord, error) {
+	rows, err := s.client.Query(s.query)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]pointio.PointRecord, 0, len(rows))
+	for _, row := range rows {
+		lat, err1 := strconv.ParseFloat(row[s.LatField], 64)
+		lon, err2 := strconv.ParseFloat(row[s.LonField], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		record := pointio.PointRecord{Lat: lat, Lon: lon, Extra: map[string]string{}}
+		if ts, ok := row[s.TimeField]; ok {
+			if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
+				record.Timestamp = &parsed
+			}
+		}
+		for name, value := range row {
+			if name == s.LatField || name == s.LonField || name == s.TimeField {
+				continue
+			}
+			record.Extra[name] = value
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Sink writes PointRecord values to bucket as line-protocol points on
+// measurement, tagged with cluster_id (taken from each record's Extra, or
+// "-1" when absent, matching the noise label).
+type Sink struct {
+	client      *Client
+	bucket      string
+	measurement string
+}
+
+// NewSink builds a Sink that writes to bucket/measurement on client.
+func NewSink(client *Client, bucket, measurement string) *Sink {
+	return &Sink{client: client, bucket: bucket, measurement: measurement}
+}
+
+func (s *Sink) Write(records []pointio.PointRecord) error {
+	lines := linesFromRecords(s.measurement, records)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return s.client.WriteLineProtocol(s.bucket, lines)
+}
+
+// linesFromRecords builds one line-protocol point per record, tagged with
+// cluster_id. Line protocol requires an event time, so records without a
+// Timestamp are skipped rather than mislabeled as "now". lat/lon are
+// formatted at full precision (not truncated to a fixed number of decimals)
+// since even small rounding matters for geographic coordinates.
+func linesFromRecords(measurement string, records []pointio.PointRecord) []string {
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Timestamp == nil {
+			continue
+		}
+
+		clusterID := record.Extra["cluster_id"]
+		if clusterID == "" {
+			clusterID = "-1"
+		}
+
+		line := fmt.Sprintf("%s,cluster_id=%s lat=%s,lon=%s %d",
+			escapeTag(measurement), escapeTag(clusterID),
+			strconv.FormatFloat(record.Lat, 'g', -1, 64), strconv.FormatFloat(record.Lon, 'g', -1, 64),
+			record.Timestamp.UnixNano())
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// escapeTag escapes the line-protocol metacharacters (comma, space, equals)
+// that can appear in a measurement name or tag value.
+func escapeTag(value string) string {
+	replacer := strings.NewReplacer(",", "\\,", " ", "\\ ", "=", "\\=")
+	return replacer.Replace(value)
+}
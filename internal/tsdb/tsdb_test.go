@@ -0,0 +1,70 @@
+package tsdb
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	pointio "go-dbscan-filter/internal/io"
+)
+
+func TestParseAnnotatedCSV(t *testing.T) {
+	csv := "_time,lat,lon\n2026-01-01T00:00:00Z,40.7128,-74.0060\n2026-01-01T00:01:00Z,40.7130,-74.0062\n"
+
+	rows, err := parseAnnotatedCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parseAnnotatedCSV returned error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0]["lat"] != "40.7128" {
+		t.Errorf("expected lat column to be preserved, got %q", rows[0]["lat"])
+	}
+}
+
+func TestSinkWriteSkipsEmptyBatch(t *testing.T) {
+	sink := NewSink(nil, "test-bucket", "points")
+
+	// With no records there is nothing to write, so the client (deliberately
+	// nil here) should never be touched.
+	if err := sink.Write(nil); err != nil {
+		t.Fatalf("Write(nil) returned error: %v", err)
+	}
+}
+
+func TestLinesFromRecordsFullPrecisionCoordinates(t *testing.T) {
+	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []pointio.PointRecord{
+		{Lat: 40.712812345678, Lon: -74.006012345678, Timestamp: &ts},
+	}
+
+	lines := linesFromRecords("points", records)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "lat=40.712812345678,lon=-74.006012345678") {
+		t.Errorf("expected full-precision coordinates, got %q", lines[0])
+	}
+}
+
+func TestLinesFromRecordsSkipsMissingTimestamp(t *testing.T) {
+	ts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []pointio.PointRecord{
+		{Lat: 1, Lon: 2, Timestamp: &ts},
+		{Lat: 3, Lon: 4, Timestamp: nil},
+	}
+
+	lines := linesFromRecords("points", records)
+	if len(lines) != 1 {
+		t.Fatalf("expected the timestamp-less record to be skipped, got %d lines: %v", len(lines), lines)
+	}
+}
+
+func TestEscapeTag(t *testing.T) {
+	got := escapeTag("a,b c=d")
+	want := `a\,b\ c\=d`
+	if got != want {
+		t.Errorf("escapeTag() = %q, want %q", got, want)
+	}
+}
@@ -1,18 +1,25 @@
 // Package main implements a DBSCAN geo point clustering tool that reads
-// geographic points from CSV files, applies DBSCAN clustering, and filters
-// results to keep only outliers and the first point in each cluster.
+// geographic points from CSV, GeoJSON, or NDJSON sources (or InfluxDB/SQL),
+// applies DBSCAN clustering, and filters results to keep only outliers and
+// one representative point per cluster.
 package main
 
 import (
-	"encoding/csv"
+	"database/sql"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
+	"sort"
 	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
 
 	"go-dbscan-filter/internal/cluster"
+	pointio "go-dbscan-filter/internal/io"
+	"go-dbscan-filter/internal/sqlio"
+	"go-dbscan-filter/internal/tsdb"
 )
 
 const (
@@ -22,274 +29,309 @@ const (
 
 func main() {
 	var (
-		inputFile  = flag.String("input", "points.csv", "Input CSV file with latitude,longitude columns")
-		outputFile = flag.String("output", "", "Output CSV file with filtered points (default: stdout)")
-		eps        = flag.Float64("eps", 0.1, "DBSCAN epsilon parameter (clustering radius in km)")
-		minPoints  = flag.Int("min-points", 3, "DBSCAN minPoints parameter (minimum points in cluster)")
-		debug      = flag.Bool("debug", false, "Enable debug output")
+		inputFile       = flag.String("input", "points.csv", "Input file with geo points")
+		outputFile      = flag.String("output", "", "Output file with filtered points (default: stdout)")
+		inputFormat     = flag.String("input-format", "", "Input format: csv, geojson, ndjson (default: auto-detect by extension)")
+		outputFormat    = flag.String("output-format", "", "Output format: csv, geojson, ndjson (default: auto-detect by extension, csv for stdout)")
+		latColumn       = flag.String("lat-column", pointio.DefaultCSVColumns.Lat, "CSV column name for latitude")
+		lonColumn       = flag.String("lon-column", pointio.DefaultCSVColumns.Lon, "CSV column name for longitude")
+		timestampColumn = flag.String("timestamp-column", "", "CSV column name for an optional timestamp")
+		inputEncoding   = flag.String("input-encoding", "", "CSV input character encoding, e.g. utf-8, utf-16le, gbk, shift-jis, iso-8859-1 (default: utf-8)")
+		outputEncoding  = flag.String("output-encoding", "", "CSV output character encoding (default: utf-8)")
+		eps             = flag.Float64("eps", 0.1, "DBSCAN epsilon parameter (clustering radius in km)")
+		epsTime         = flag.Duration("eps-time", 0, "ST-DBSCAN temporal epsilon; when set, clustering also requires points to fall within this duration of each other")
+		minPoints       = flag.Int("min-points", 3, "DBSCAN minPoints parameter (minimum points in cluster)")
+		representative  = flag.String("representative", "first", "Cluster representative selection strategy: first, centroid, medoid, density-peak")
+		debug           = flag.Bool("debug", false, "Enable debug output")
+
+		influxURL         = flag.String("influx-url", "", "InfluxDB v2 base URL; when set, points are read from Influx instead of --input")
+		influxToken       = flag.String("influx-token", "", "InfluxDB v2 API token")
+		influxOrg         = flag.String("influx-org", "", "InfluxDB v2 organization")
+		influxQuery       = flag.String("influx-query", "", "Flux query returning lat/lon/time columns")
+		influxLatField    = flag.String("influx-lat-field", "lat", "Influx query result column for latitude")
+		influxLonField    = flag.String("influx-lon-field", "lon", "Influx query result column for longitude")
+		influxTimeField   = flag.String("influx-time-field", "_time", "Influx query result column for the point timestamp")
+		influxMeasurement = flag.String("influx-measurement", "filtered_points", "Measurement name for points written back to Influx")
+		influxWriteBucket = flag.String("influx-write-bucket", "", "InfluxDB v2 bucket to write filtered cluster representatives to")
+
+		sqlDriver      = flag.String("sql-driver", "", "database/sql driver name (e.g. postgres, mysql, sqlite3); when set, points are read from --sql-source-table instead of --input (and written to --sql-sink-table, when that is set)")
+		sqlDSN         = flag.String("sql-dsn", "", "database/sql data source name")
+		sqlSourceTable = flag.String("sql-source-table", "", "Table to read points from (loaded into memory in full, like the other sources - DBSCAN needs the whole point set)")
+		sqlSourceWhere = flag.String("sql-source-where", "", "Raw SQL WHERE expression to filter the source table")
+		sqlSinkTable   = flag.String("sql-sink-table", "", "Table to write filtered points to")
 	)
 	flag.Parse()
 
-	// Read points and CSV records from file (read once, reuse for output)
-	points, csvRecords, err := readPointsAndCSV(*inputFile)
+	columns := pointio.CSVColumns{Lat: *latColumn, Lon: *lonColumn, Timestamp: *timestampColumn}
+
+	representativeStrategy, err := cluster.ParseRepresentative(*representative)
 	if err != nil {
-		log.Fatalf("Error reading CSV: %v", err)
+		log.Fatalf("Error parsing --representative: %v", err)
 	}
 
-	if len(points) == 0 {
-		log.Fatalf("No points found in CSV file")
+	var influxClient *tsdb.Client
+	if *influxURL != "" {
+		influxClient = tsdb.NewClient(*influxURL, *influxToken, *influxOrg)
 	}
 
-	// Debug output (only if debug flag is set)
-	if *debug {
-		fmt.Printf("Read %d points from %s\n", len(points), *inputFile)
-		fmt.Printf("Running DBSCAN with eps=%.4f km, minPoints=%d\n", *eps, *minPoints)
+	var db *sql.DB
+	if *sqlDriver != "" {
+		var err error
+		db, err = sql.Open(*sqlDriver, *sqlDSN)
+		if err != nil {
+			log.Fatalf("Error opening SQL database: %v", err)
+		}
+		defer func() {
+			if closeErr := db.Close(); closeErr != nil {
+				log.Printf("Error closing SQL database: %v", closeErr)
+			}
+		}()
 	}
 
-	// Run DBSCAN clustering
-	clusters, noise := cluster.DBScan(points, *eps, *minPoints)
-
-	if *debug {
-		fmt.Printf("Found %d clusters\n", len(clusters))
-		fmt.Printf("Found %d noise points\n", len(noise))
+	// Read point records, from the input file, Influx, or SQL (read once,
+	// reuse for output)
+	var records []pointio.PointRecord
+	switch {
+	case db != nil:
+		var where sq.Sqlizer
+		if *sqlSourceWhere != "" {
+			where = sq.Expr(*sqlSourceWhere)
+		}
+		source := sqlio.NewSQLSource(db, *sqlSourceTable, *latColumn, *lonColumn, where, *sqlDriver)
+		records, err = source.Read()
+		if err != nil {
+			log.Fatalf("Error reading SQL source table: %v", err)
+		}
+	case influxClient != nil:
+		source := tsdb.NewSource(influxClient, *influxQuery, *influxLatField, *influxLonField, *influxTimeField)
+		records, err = source.Read()
+		if err != nil {
+			log.Fatalf("Error querying Influx: %v", err)
+		}
+	default:
+		records, err = readRecords(*inputFile, *inputFormat, columns, *inputEncoding)
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", *inputFile, err)
+		}
 	}
 
-	// Build labels array from clusters and noise for filtering
-	labels := buildLabels(clusters, noise, len(points))
+	if len(records) == 0 {
+		log.Fatalf("No points found")
+	}
 
-	// Filter points based on Ruby logic:
-	// 1. Keep outliers (label == -1)
-	// 2. Keep first point in each cluster (idx == 0 or label != labels[idx-1])
-	filteredIndices := filterPoints(labels)
+	points := pointsFromRecords(records)
 
+	// Debug output (only if debug flag is set)
 	if *debug {
-		fmt.Printf("Filtered to %d points\n", len(filteredIndices))
+		fmt.Printf("Read %d points\n", len(points))
+		fmt.Printf("Running DBSCAN with eps=%.4f km, minPoints=%d\n", *eps, *minPoints)
 	}
 
-	// Write filtered points to output (stdout or file)
-	if *outputFile == "" {
-		// Output to stdout as simple list of points
-		err = writeFilteredPointsToStdout(csvRecords, filteredIndices)
+	// Run DBSCAN clustering, or its spatiotemporal variant when --eps-time
+	// is set
+	var (
+		clusters []cluster.Cluster
+		noise    []int
+	)
+	if *epsTime > 0 {
+		times, err := timesFromRecords(records)
 		if err != nil {
-			log.Fatalf("Error writing to stdout: %v", err)
+			log.Fatalf("Error preparing ST-DBSCAN input: %v", err)
 		}
+		clusters, noise = cluster.STDBScan(points, times, *eps, *epsTime, *minPoints)
 	} else {
-		// Write filtered points to output CSV file
-		err = writeFilteredPointsToCSV(outputFile, csvRecords, filteredIndices)
-		if err != nil {
-			log.Fatalf("Error writing CSV: %v", err)
-		}
-		if *debug {
-			fmt.Printf("Filtered points written to %s\n", *outputFile)
-		}
+		clusters, noise = cluster.DBScan(points, *eps, *minPoints)
 	}
-}
 
-// readPointsAndCSV reads points and CSV records from a file in a single pass
-// Expected format: latitude,longitude (header row is optional)
-// Returns points for clustering and raw records for output preservation
-func readPointsAndCSV(filename string) (cluster.PointList, [][]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, nil, err
+	if *debug {
+		fmt.Printf("Found %d clusters\n", len(clusters))
+		fmt.Printf("Found %d noise points\n", len(noise))
 	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			log.Printf("Error closing file: %v", closeErr)
-		}
-	}()
 
-	reader := csv.NewReader(file)
-	points := cluster.PointList{}
-	records := [][]string{}
+	// Keep every outlier plus one representative per cluster, chosen by the
+	// --representative strategy.
+	filtered := selectOutputRecords(records, points, clusters, noise, representativeStrategy, *eps, *minPoints)
 
-	// Read all records first
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
+	if *debug {
+		fmt.Printf("Filtered to %d points\n", len(filtered))
+	}
+
+	switch {
+	case *sqlSinkTable != "":
+		sink := sqlio.NewSQLSink(db, *sqlSinkTable, *sqlDriver)
+		if err := sink.Write(filtered); err != nil {
+			log.Fatalf("Error writing to SQL sink table: %v", err)
 		}
-		if err != nil {
-			return nil, nil, err
+		return
+	case *influxWriteBucket != "":
+		sink := tsdb.NewSink(influxClient, *influxWriteBucket, *influxMeasurement)
+		if err := sink.Write(filtered); err != nil {
+			log.Fatalf("Error writing to Influx: %v", err)
 		}
-		records = append(records, record)
+		return
 	}
 
-	if len(records) == 0 {
-		return points, records, nil
+	if err := writeRecords(filtered, *outputFile, *outputFormat, columns, *outputEncoding); err != nil {
+		log.Fatalf("Error writing output: %v", err)
 	}
-
-	// Determine if first row is header
-	hasHeader := false
-	_, err = strconv.ParseFloat(records[0][0], 64)
-	if err != nil {
-		hasHeader = true
+	if *debug && *outputFile != "" {
+		fmt.Printf("Filtered points written to %s\n", *outputFile)
 	}
+}
 
-	// Parse points from records
-	startIdx := 0
-	if hasHeader {
-		startIdx = 1
+// withClusterID returns a copy of record with its Extra map carrying the
+// cluster label, so downstream sinks (notably tsdb.Sink) can tag output
+// points with cluster_id.
+func withClusterID(record pointio.PointRecord, label int) pointio.PointRecord {
+	extra := make(map[string]string, len(record.Extra)+1)
+	for k, v := range record.Extra {
+		extra[k] = v
 	}
+	extra["cluster_id"] = strconv.Itoa(label)
+	record.Extra = extra
+	return record
+}
 
-	for i := startIdx; i < len(records); i++ {
-		record := records[i]
-		if len(record) < 2 {
-			continue
-		}
-
-		lat, err1 := strconv.ParseFloat(record[0], 64)
-		lon, err2 := strconv.ParseFloat(record[1], 64)
-		if err1 != nil || err2 != nil {
-			continue // Skip invalid rows
-		}
-
-		// Point is [2]float64 where [0]=Lon, [1]=Lat
-		points = append(points, cluster.Point{lon, lat})
+// selectOutputRecords keeps every outlier plus one representative per
+// cluster (chosen by strategy), tagging each with its cluster_id (-1 for
+// outliers) and restoring the original input order. A Centroid-style
+// synthetic representative has no input record of its own, so it copies
+// Extra from its nearest actual cluster member.
+func selectOutputRecords(records []pointio.PointRecord, points cluster.PointList, clusters []cluster.Cluster, noise []int, strategy cluster.Representative, eps float64, minPoints int) []pointio.PointRecord {
+	type keyed struct {
+		sortKey int
+		record  pointio.PointRecord
 	}
 
-	return points, records, nil
-}
+	output := make([]keyed, 0, len(noise)+len(clusters))
 
-// filterPoints filters points based on the Ruby logic:
-// - Keep outliers (label == -1)
-// - Keep first point in each cluster (idx == 0 or label != labels[idx-1])
-func filterPoints(labels []int) []int {
-	filtered := []int{}
+	for _, idx := range noise {
+		output = append(output, keyed{sortKey: idx, record: withClusterID(records[idx], DbscanOutlierIndex)})
+	}
 
-	for idx, label := range labels {
-		// Keep if it's an outlier
-		if label == DbscanOutlierIndex {
-			filtered = append(filtered, idx)
-			continue
-		}
+	for _, c := range clusters {
+		result := strategy.Select(points, c.Points, eps, minPoints)
 
-		// Keep if it's the first point (idx == 0)
-		if idx == 0 {
-			filtered = append(filtered, idx)
+		if !result.Synthetic {
+			output = append(output, keyed{sortKey: result.Index, record: withClusterID(records[result.Index], c.C)})
 			continue
 		}
 
-		// Keep if it's the first point in a cluster (label != previous label)
-		if label != labels[idx-1] {
-			filtered = append(filtered, idx)
-		}
+		synthetic := withClusterID(records[result.NearestIndex], c.C)
+		synthetic.Lat = result.Point[1]
+		synthetic.Lon = result.Point[0]
+		output = append(output, keyed{sortKey: result.NearestIndex, record: synthetic})
 	}
 
-	return filtered
-}
-
-// buildLabels creates a labels array from clusters and noise
-// labels[i] = cluster ID for point i, or -1 for noise
-func buildLabels(clusters []cluster.Cluster, _ []int, numPoints int) []int {
-	labels := make([]int, numPoints)
+	sort.Slice(output, func(i, j int) bool { return output[i].sortKey < output[j].sortKey })
 
-	// Initialize all as noise
-	for i := range labels {
-		labels[i] = -1
+	filtered := make([]pointio.PointRecord, len(output))
+	for i, item := range output {
+		filtered[i] = item.record
 	}
+	return filtered
+}
 
-	// Mark cluster points
-	for _, cluster := range clusters {
-		for _, idx := range cluster.Points {
-			labels[idx] = cluster.C
+// timesFromRecords extracts the Timestamp of each record for ST-DBSCAN. A
+// record without one would otherwise silently fall back to the zero time -
+// infinitely far in the past from every real timestamp - and get dropped
+// to noise, so that case is rejected outright instead.
+func timesFromRecords(records []pointio.PointRecord) ([]time.Time, error) {
+	times := make([]time.Time, len(records))
+	for i, record := range records {
+		if record.Timestamp == nil {
+			return nil, fmt.Errorf("record %d has no timestamp, required when --eps-time is set", i)
 		}
+		times[i] = *record.Timestamp
 	}
-
-	// Noise points are already -1, but we verify they're in the noise list
-	// (they should already be -1 from initialization)
-
-	return labels
+	return times, nil
 }
 
-// writeFilteredPointsToCSV writes filtered points to output CSV
-// Uses pre-read CSV records to preserve any additional columns
-func writeFilteredPointsToCSV(outputFile *string, csvRecords [][]string, filteredIndices []int) error {
-	// Create a set of filtered indices for quick lookup
-	filteredSet := make(map[int]bool)
-	for _, idx := range filteredIndices {
-		filteredSet[idx] = true
-	}
-
-	// Write filtered records to output
-	outFile, err := os.Create(*outputFile)
+// readRecords opens filename and reads it through the Source matching
+// inputFormat (or the file's extension, when inputFormat is empty).
+// inputEncoding only applies to the CSV format; it's ignored otherwise.
+func readRecords(filename, inputFormat string, columns pointio.CSVColumns, inputEncoding string) ([]pointio.PointRecord, error) {
+	file, err := os.Open(filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
-		if closeErr := outFile.Close(); closeErr != nil {
-			log.Printf("Error closing output file: %v", closeErr)
+		if closeErr := file.Close(); closeErr != nil {
+			log.Printf("Error closing file: %v", closeErr)
 		}
 	}()
 
-	writer := csv.NewWriter(outFile)
-	defer writer.Flush()
+	format, err := pointio.DetectFormat(inputFormat, filename)
+	if err != nil {
+		return nil, err
+	}
 
-	// Determine if first row is header
-	hasHeader := false
-	if len(csvRecords) > 0 {
-		_, err := strconv.ParseFloat(csvRecords[0][0], 64)
+	var source pointio.Source
+	switch format {
+	case pointio.FormatGeoJSON:
+		source = pointio.NewGeoJSONSource(file)
+	case pointio.FormatNDJSON:
+		source = pointio.NewNDJSONSource(file)
+	default:
+		decoded, err := pointio.DecodingReader(file, inputEncoding)
 		if err != nil {
-			hasHeader = true
-			// Write header
-			if err := writer.Write(csvRecords[0]); err != nil {
-				return err
-			}
+			return nil, err
 		}
+		source = pointio.NewCSVSource(decoded, columns)
 	}
 
-	// Write filtered data rows
-	startIdx := 0
-	if hasHeader {
-		startIdx = 1
-	}
+	return source.Read()
+}
 
-	for i := startIdx; i < len(csvRecords); i++ {
-		pointIdx := i - startIdx
-		if filteredSet[pointIdx] {
-			if err := writer.Write(csvRecords[i]); err != nil {
-				return err
-			}
+// writeRecords writes records to outputFile (or stdout, when outputFile is
+// empty) through the Sink matching outputFormat (or the file's extension).
+// outputEncoding only applies to the CSV format; it's ignored otherwise.
+func writeRecords(records []pointio.PointRecord, outputFile, outputFormat string, columns pointio.CSVColumns, outputEncoding string) error {
+	out := os.Stdout
+	filenameForDetection := outputFile
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return err
 		}
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil {
+				log.Printf("Error closing output file: %v", closeErr)
+			}
+		}()
+		out = file
+	} else if outputFormat == "" {
+		filenameForDetection = ".csv" // stdout defaults to CSV when format isn't given
 	}
 
-	return nil
-}
-
-// writeFilteredPointsToStdout writes filtered points to stdout as a simple list
-// Format: latitude,longitude (one point per line)
-// Uses pre-read CSV records to preserve order
-func writeFilteredPointsToStdout(csvRecords [][]string, filteredIndices []int) error {
-	// Create a set of filtered indices for quick lookup
-	filteredSet := make(map[int]bool)
-	for _, idx := range filteredIndices {
-		filteredSet[idx] = true
+	format, err := pointio.DetectFormat(outputFormat, filenameForDetection)
+	if err != nil {
+		return err
 	}
 
-	// Determine if first row is header
-	hasHeader := false
-	if len(csvRecords) > 0 {
-		_, err := strconv.ParseFloat(csvRecords[0][0], 64)
+	var sink pointio.Sink
+	switch format {
+	case pointio.FormatGeoJSON:
+		sink = pointio.NewGeoJSONSink(out)
+	case pointio.FormatNDJSON:
+		sink = pointio.NewNDJSONSink(out)
+	default:
+		encoded, err := pointio.EncodingWriter(out, outputEncoding)
 		if err != nil {
-			hasHeader = true
+			return err
 		}
+		sink = pointio.NewCSVSink(encoded, columns)
 	}
 
-	// Write filtered points to stdout
-	startIdx := 0
-	if hasHeader {
-		startIdx = 1
-	}
+	return sink.Write(records)
+}
 
-	for i := startIdx; i < len(csvRecords); i++ {
-		pointIdx := i - startIdx
-		if filteredSet[pointIdx] {
-			// Output as: latitude,longitude
-			if len(csvRecords[i]) >= 2 {
-				fmt.Printf("%s,%s\n", csvRecords[i][0], csvRecords[i][1])
-			}
-		}
+// pointsFromRecords converts point records into the cluster.Point values
+// DBSCAN operates on. Point is [2]float64 where [0]=Lon, [1]=Lat.
+func pointsFromRecords(records []pointio.PointRecord) cluster.PointList {
+	points := make(cluster.PointList, len(records))
+	for i, record := range records {
+		points[i] = cluster.Point{record.Lon, record.Lat}
 	}
-
-	return nil
+	return points
 }
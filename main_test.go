@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"go-dbscan-filter/internal/cluster"
+	pointio "go-dbscan-filter/internal/io"
 )
 
 func TestMainProgram(t *testing.T) {
@@ -26,10 +27,11 @@ func TestMainProgram(t *testing.T) {
 	defer os.Remove("test_points.csv")
 
 	// Read points
-	points, err := readPointsFromCSV("test_points.csv")
+	records, err := readRecords("test_points.csv", "", pointio.DefaultCSVColumns, "")
 	if err != nil {
 		t.Fatalf("Failed to read CSV: %v", err)
 	}
+	points := pointsFromRecords(records)
 
 	if len(points) != 8 {
 		t.Errorf("Expected 8 points, got %d", len(points))
@@ -42,16 +44,13 @@ func TestMainProgram(t *testing.T) {
 		t.Error("Expected at least one cluster")
 	}
 
-	// Build labels and test filtering
-	labels := buildLabels(clusters, noise, len(points))
-	filteredIndices := filterPoints(labels)
+	filtered := selectOutputRecords(records, points, clusters, noise, cluster.FirstInInput{}, 0.1, 3)
 
-	// Verify filtering logic:
 	// 1. All outliers should be included
 	for _, noiseIdx := range noise {
 		found := false
-		for _, idx := range filteredIndices {
-			if idx == noiseIdx {
+		for _, record := range filtered {
+			if record.Lat == records[noiseIdx].Lat && record.Lon == records[noiseIdx].Lon {
 				found = true
 				break
 			}
@@ -61,87 +60,53 @@ func TestMainProgram(t *testing.T) {
 		}
 	}
 
-	// 2. First point in each cluster should be included
-	for _, cluster := range clusters {
-		if len(cluster.Points) > 0 {
-			firstPoint := cluster.Points[0]
-			found := false
-			for _, idx := range filteredIndices {
-				if idx == firstPoint {
-					found = true
-					break
-				}
-			}
-			if !found {
-				t.Errorf("First point of cluster %d (index %d) should be in filtered results", cluster.C, firstPoint)
-			}
-		}
+	// 2. Exactly one representative per cluster
+	if len(filtered) != len(noise)+len(clusters) {
+		t.Errorf("Expected %d filtered points (noise + one per cluster), got %d", len(noise)+len(clusters), len(filtered))
 	}
 
-	// 3. First point overall should be included
-	if len(filteredIndices) == 0 || filteredIndices[0] != 0 {
-		// Check if 0 is in filtered indices
-		found := false
-		for _, idx := range filteredIndices {
-			if idx == 0 {
-				found = true
-				break
-			}
-		}
-		if !found && labels[0] != -1 {
-			t.Error("First point (index 0) should be in filtered results if it's not noise")
+	// 3. Every filtered record is tagged with its cluster_id
+	for _, record := range filtered {
+		if _, ok := record.Extra["cluster_id"]; !ok {
+			t.Errorf("Expected every filtered record to carry cluster_id, got %+v", record)
 		}
 	}
 
-	t.Logf("Test passed: %d clusters, %d noise points, %d filtered points", len(clusters), len(noise), len(filteredIndices))
+	t.Logf("Test passed: %d clusters, %d noise points, %d filtered points", len(clusters), len(noise), len(filtered))
 }
 
-func TestFilterPointsLogic(t *testing.T) {
-	// Test the Ruby-style filtering logic
+func TestSelectOutputRecordsRepresentativeStrategies(t *testing.T) {
+	records := []pointio.PointRecord{
+		{Lat: 40.7128, Lon: -74.0060, Extra: map[string]string{"name": "a"}},
+		{Lat: 40.7130, Lon: -74.0062, Extra: map[string]string{"name": "b"}},
+		{Lat: 41.0000, Lon: -74.0000, Extra: map[string]string{"name": "outlier"}},
+	}
+	points := pointsFromRecords(records)
+	clusters := []cluster.Cluster{{C: 0, Points: []int{0, 1}}}
+	noise := []int{2}
+
 	tests := []struct {
-		name           string
-		labels         []int
-		expectedCount  int
-		expectedIndices []int
+		name     string
+		strategy cluster.Representative
 	}{
-		{
-			name:           "all outliers",
-			labels:         []int{-1, -1, -1},
-			expectedCount:  3,
-			expectedIndices: []int{0, 1, 2},
-		},
-		{
-			name:           "single cluster",
-			labels:         []int{0, 0, 0},
-			expectedCount:  1,
-			expectedIndices: []int{0},
-		},
-		{
-			name:           "two clusters",
-			labels:         []int{0, 0, 1, 1},
-			expectedCount:  2,
-			expectedIndices: []int{0, 2},
-		},
-		{
-			name:           "mixed outliers and clusters",
-			labels:         []int{-1, 0, 0, -1, 1, 1},
-			expectedCount:  4,
-			expectedIndices: []int{0, 1, 3, 4},
-		},
+		{name: "first", strategy: cluster.FirstInInput{}},
+		{name: "centroid", strategy: cluster.Centroid{}},
+		{name: "medoid", strategy: cluster.Medoid{}},
+		{name: "density-peak", strategy: cluster.DensityPeak{}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := filterPoints(tt.labels)
-			if len(result) != tt.expectedCount {
-				t.Errorf("Expected %d filtered points, got %d", tt.expectedCount, len(result))
+			filtered := selectOutputRecords(records, points, clusters, noise, tt.strategy, 0.1, 2)
+			if len(filtered) != 2 {
+				t.Fatalf("expected 1 outlier + 1 representative, got %d records", len(filtered))
+			}
+			if filtered[0].Extra["cluster_id"] != "0" {
+				t.Errorf("expected the cluster representative first (sorted by original index), got %+v", filtered[0])
 			}
-			for i, expectedIdx := range tt.expectedIndices {
-				if i < len(result) && result[i] != expectedIdx {
-					t.Errorf("Expected index %d at position %d, got %d", expectedIdx, i, result[i])
-				}
+			if filtered[1].Extra["cluster_id"] != "-1" {
+				t.Errorf("expected the outlier last, got %+v", filtered[1])
 			}
 		})
 	}
 }
-